@@ -3,6 +3,7 @@ package keyvalue
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,9 +21,15 @@ const (
 
 // Client is the Key-Value API client
 type Client struct {
-	BaseURL    string
-	Token      string
-	HTTPClient *http.Client
+	BaseURL     string
+	Token       string
+	HTTPClient  *http.Client
+	RetryPolicy RetryPolicy
+	// Codec serializes values before Store and deserializes them after
+	// Retrieve; it defaults to plain JSON. Transforms run on the codec's
+	// output, outermost first on the way out and last on the way back in.
+	Codec      Codec
+	Transforms []Transformer
 }
 
 // NewClient creates a new Key-Value client
@@ -107,6 +114,9 @@ type HistoryEvent struct {
 	TextValue      *string         `json:"text_value"`
 	Confidence     *float64        `json:"confidence"`
 	Payload        json.RawMessage `json:"payload"`
+	// TransformErr is set when Payload could not be decoded by the client's
+	// configured Codec/Transform chain; it is not part of the wire format.
+	TransformErr error `json:"-"`
 }
 
 // HistoryResponse represents the response from querying history
@@ -156,6 +166,9 @@ type BatchResult struct {
 	Data    json.RawMessage `json:"data,omitempty"`
 	Version *int            `json:"version,omitempty"`
 	Error   string          `json:"error,omitempty"`
+	// TransformErr is set when Data could not be decoded by the client's
+	// configured Codec/Transform chain; it is not part of the wire format.
+	TransformErr error `json:"-"`
 }
 
 // BatchResponse represents the response from a batch operation
@@ -171,19 +184,21 @@ type BatchResponse struct {
 }
 
 // Generate creates a new 5-word memorable token
-func (c *Client) Generate(turnstileToken ...string) (*GenerateResponse, error) {
+func (c *Client) Generate(ctx context.Context, turnstileToken ...string) (*GenerateResponse, error) {
 	var payload map[string]string
 	if len(turnstileToken) > 0 {
 		payload = map[string]string{"turnstileToken": turnstileToken[0]}
 	}
 
 	var resp GenerateResponse
-	err := c.request("POST", "/api/generate", payload, nil, &resp)
+	err := c.request(ctx, "POST", "/api/generate", payload, nil, &resp)
 	return &resp, err
 }
 
-// Store stores JSON data with a token
-func (c *Client) Store(data interface{}, token string, ttl *int) (*StoreResponse, error) {
+// Store stores JSON data with a token. An optional version may be passed to
+// require the write to apply only if the stored value is still at that
+// version; a mismatch returns an *APIError wrapping ErrConflict.
+func (c *Client) Store(ctx context.Context, data interface{}, token string, ttl *int, version ...int) (*StoreResponse, error) {
 	if token == "" {
 		token = c.Token
 	}
@@ -192,19 +207,28 @@ func (c *Client) Store(data interface{}, token string, ttl *int) (*StoreResponse
 	}
 
 	payload := map[string]interface{}{"data": data}
+	if encoded, ok, err := c.encodeValue(data); ok {
+		if err != nil {
+			return nil, err
+		}
+		payload["data"] = encoded
+	}
 	if ttl != nil {
 		payload["ttl"] = *ttl
 	}
+	if len(version) > 0 {
+		payload["version"] = version[0]
+	}
 
 	headers := map[string]string{"X-KV-Token": token}
 
 	var resp StoreResponse
-	err := c.request("POST", "/api/store", payload, headers, &resp)
+	err := c.request(ctx, "POST", "/api/store", payload, headers, &resp)
 	return &resp, err
 }
 
 // Retrieve retrieves data for a token
-func (c *Client) Retrieve(token string) (*RetrieveResponse, error) {
+func (c *Client) Retrieve(ctx context.Context, token string) (*RetrieveResponse, error) {
 	if token == "" {
 		token = c.Token
 	}
@@ -215,12 +239,20 @@ func (c *Client) Retrieve(token string) (*RetrieveResponse, error) {
 	headers := map[string]string{"X-KV-Token": token}
 
 	var resp RetrieveResponse
-	err := c.request("GET", "/api/retrieve", nil, headers, &resp)
-	return &resp, err
+	if err := c.request(ctx, "GET", "/api/retrieve", nil, headers, &resp); err != nil {
+		return nil, err
+	}
+
+	plain, err := c.decodeStoredValue(token, resp.Data)
+	if err != nil {
+		return &resp, err
+	}
+	resp.Data = plain
+	return &resp, nil
 }
 
 // Delete deletes data for a token
-func (c *Client) Delete(token string) (*DeleteResponse, error) {
+func (c *Client) Delete(ctx context.Context, token string) (*DeleteResponse, error) {
 	if token == "" {
 		token = c.Token
 	}
@@ -231,12 +263,12 @@ func (c *Client) Delete(token string) (*DeleteResponse, error) {
 	headers := map[string]string{"X-KV-Token": token}
 
 	var resp DeleteResponse
-	err := c.request("DELETE", "/api/delete", nil, headers, &resp)
+	err := c.request(ctx, "DELETE", "/api/delete", nil, headers, &resp)
 	return &resp, err
 }
 
 // Patch applies atomic partial updates with optimistic concurrency
-func (c *Client) Patch(version int, patch *PatchOperations, token string, ttl *int) (*PatchResponse, error) {
+func (c *Client) Patch(ctx context.Context, version int, patch *PatchOperations, token string, ttl *int) (*PatchResponse, error) {
 	if token == "" {
 		token = c.Token
 	}
@@ -244,9 +276,14 @@ func (c *Client) Patch(version int, patch *PatchOperations, token string, ttl *i
 		return nil, fmt.Errorf("token is required")
 	}
 
+	encodedPatch, err := c.encodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
 	payload := map[string]interface{}{
 		"version": version,
-		"patch":   patch,
+		"patch":   encodedPatch,
 	}
 	if ttl != nil {
 		payload["ttl"] = *ttl
@@ -255,8 +292,16 @@ func (c *Client) Patch(version int, patch *PatchOperations, token string, ttl *i
 	headers := map[string]string{"X-KV-Token": token}
 
 	var resp PatchResponse
-	err := c.request("PATCH", "/api/store", payload, headers, &resp)
-	return &resp, err
+	if err := c.request(ctx, "PATCH", "/api/store", payload, headers, &resp); err != nil {
+		return nil, err
+	}
+
+	plain, err := c.decodeStoredValue(token, resp.Data)
+	if err != nil {
+		return &resp, err
+	}
+	resp.Data = plain
+	return &resp, nil
 }
 
 // HistoryOptions defines options for querying history
@@ -268,7 +313,7 @@ type HistoryOptions struct {
 }
 
 // History queries time-series event history
-func (c *Client) History(token string, opts *HistoryOptions) (*HistoryResponse, error) {
+func (c *Client) History(ctx context.Context, token string, opts *HistoryOptions) (*HistoryResponse, error) {
 	if token == "" {
 		token = c.Token
 	}
@@ -300,12 +345,27 @@ func (c *Client) History(token string, opts *HistoryOptions) (*HistoryResponse,
 	headers := map[string]string{"X-KV-Token": token}
 
 	var resp HistoryResponse
-	err := c.request("GET", path, nil, headers, &resp)
-	return &resp, err
+	if err := c.request(ctx, "GET", path, nil, headers, &resp); err != nil {
+		return nil, err
+	}
+
+	for i := range resp.Events {
+		if len(resp.Events[i].Payload) == 0 {
+			continue
+		}
+		plain, err := c.decodeStoredValue(token, resp.Events[i].Payload)
+		if err != nil {
+			resp.Events[i].TransformErr = err
+			continue
+		}
+		resp.Events[i].Payload = plain
+	}
+
+	return &resp, nil
 }
 
 // Batch executes multiple operations in a single request
-func (c *Client) Batch(operations []BatchOperation) (*BatchResponse, error) {
+func (c *Client) Batch(ctx context.Context, operations []BatchOperation) (*BatchResponse, error) {
 	if len(operations) == 0 {
 		return nil, fmt.Errorf("at least one operation is required")
 	}
@@ -313,25 +373,90 @@ func (c *Client) Batch(operations []BatchOperation) (*BatchResponse, error) {
 		return nil, fmt.Errorf("maximum 100 operations per batch")
 	}
 
+	if c.usesTransform() {
+		encodedOps := make([]BatchOperation, len(operations))
+		for i, op := range operations {
+			if op.Data != nil {
+				encoded, _, err := c.encodeValue(op.Data)
+				if err != nil {
+					return nil, err
+				}
+				op.Data = encoded
+			}
+			encodedPatch, err := c.encodePatch(op.Patch)
+			if err != nil {
+				return nil, err
+			}
+			op.Patch = encodedPatch
+			encodedOps[i] = op
+		}
+		operations = encodedOps
+	}
+
 	payload := map[string]interface{}{"operations": operations}
 
 	var resp BatchResponse
-	err := c.request("POST", "/api/batch", payload, nil, &resp)
-	return &resp, err
+	if err := c.request(ctx, "POST", "/api/batch", payload, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	for i := range resp.Results {
+		if len(resp.Results[i].Data) == 0 {
+			continue
+		}
+		plain, err := c.decodeStoredValue(resp.Results[i].Token, resp.Results[i].Data)
+		if err != nil {
+			resp.Results[i].TransformErr = err
+			continue
+		}
+		resp.Results[i].Data = plain
+	}
+
+	return &resp, nil
 }
 
-// request is the internal HTTP request handler
-func (c *Client) request(method, path string, body interface{}, headers map[string]string, result interface{}) error {
-	var bodyReader io.Reader
+// request is the internal HTTP request handler. It retries transient
+// failures according to c.RetryPolicy, which defaults to zero retries.
+func (c *Client) request(ctx context.Context, method, path string, body interface{}, headers map[string]string, result interface{}) error {
+	var bodyBytes []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonData)
 	}
 
-	req, err := http.NewRequest(method, c.BaseURL+path, bodyReader)
+	attempts := c.RetryPolicy.maxAttempts(method)
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := c.waitBeforeRetry(ctx, attempt, lastErr); err != nil {
+				return err
+			}
+		}
+
+		lastErr = c.doRequest(ctx, method, path, bodyBytes, headers, result)
+		if lastErr == nil {
+			return nil
+		}
+		if attempt == attempts-1 || !Retryable(lastErr) {
+			return lastErr
+		}
+	}
+
+	return lastErr
+}
+
+// doRequest performs a single HTTP round trip with no retry logic.
+func (c *Client) doRequest(ctx context.Context, method, path string, bodyBytes []byte, headers map[string]string, result interface{}) error {
+	var bodyReader io.Reader
+	if bodyBytes != nil {
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bodyReader)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -360,6 +485,7 @@ func (c *Client) request(method, path string, body interface{}, headers map[stri
 		return &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    errResp.Error,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
 		}
 	}
 
@@ -374,8 +500,30 @@ func (c *Client) request(method, path string, body interface{}, headers map[stri
 type APIError struct {
 	StatusCode int
 	Message    string
+	// RetryAfter is the server-requested delay before retrying, parsed from
+	// the error response's Retry-After header (typically sent with a 429).
+	// It is zero when the header was absent or unparsable.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
 	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
 }
+
+// Unwrap exposes a sentinel error matching e.StatusCode, if any, so callers
+// can use errors.Is(err, keyvalue.ErrConflict) instead of switching on raw
+// status codes.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,33 @@
+package keyvalue
+
+import "encoding/json"
+
+// Codec marshals a value to bytes before it enters the Transform chain on
+// the way to the server, and unmarshals bytes back into a value once the
+// chain has decoded them on the way back. The default Codec is plain JSON.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// jsonCodec is the Client default, used whenever WithCodec has not been
+// passed.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// WithCodec replaces the default JSON codec used to serialize values before
+// Store and deserialize them after Retrieve.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) {
+		c.Codec = codec
+	}
+}
+
+func (c *Client) codec() Codec {
+	if c.Codec != nil {
+		return c.Codec
+	}
+	return jsonCodec{}
+}
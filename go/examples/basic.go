@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,6 +11,8 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
+
 	// Get token from environment or generate new one
 	token := os.Getenv("KV_TOKEN")
 
@@ -17,7 +20,7 @@ func main() {
 
 	if token == "" {
 		fmt.Println("=== Generating Token ===")
-		resp, err := client.Generate()
+		resp, err := client.Generate(ctx)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -42,7 +45,7 @@ func main() {
 		"scores": []int{95, 87, 92},
 	}
 
-	storeResp, err := client.Store(data, "", nil)
+	storeResp, err := client.Store(ctx, data, "", nil)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -54,7 +57,7 @@ func main() {
 
 	// Retrieve data
 	fmt.Println("=== Retrieving Data ===")
-	retrieveResp, err := client.Retrieve("")
+	retrieveResp, err := client.Retrieve(ctx, "")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,120 @@
+package keyvalue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	// conflictBaseDelay and conflictMaxDelay bound the backoff between
+	// GuaranteedUpdate's conflict retries. Conflicts are expected to clear
+	// within one or two competing writers, so these are much shorter than
+	// RetryPolicy's HTTP-failure backoff, just enough to avoid every loser
+	// of a conflict hammering the server with an immediate re-read.
+	conflictBaseDelay = 10 * time.Millisecond
+	conflictMaxDelay  = 500 * time.Millisecond
+)
+
+// conflictBackoff returns the exponential delay, with full jitter, before
+// retrying after the given conflict attempt (1-indexed).
+func conflictBackoff(attempt int) time.Duration {
+	delay := conflictBaseDelay << uint(attempt-1)
+	if delay <= 0 || delay > conflictMaxDelay {
+		delay = conflictMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// ErrNoUpdateNeeded can be returned by a GuaranteedUpdate tryUpdate callback
+// to abort the read-modify-write loop without writing anything, e.g. when
+// the callback determines the current value already satisfies the desired
+// state.
+var ErrNoUpdateNeeded = errors.New("key-value: no update needed")
+
+// GuaranteedUpdateOptions lets a caller who already holds a fresh read skip
+// GuaranteedUpdate's initial Retrieve, mirroring etcd's origStateIsCurrent
+// fast path. If the cached version turns out to be stale, GuaranteedUpdate
+// falls back to a fresh Retrieve and retries, the same as any other
+// conflict.
+type GuaranteedUpdateOptions struct {
+	CachedVersion int
+	CachedData    []byte
+}
+
+// GuaranteedUpdate implements an optimistic-concurrency read-modify-write
+// loop against a single token, modeled on the etcd3 store pattern used by
+// kube-apiserver. It retrieves the current value and version, invokes
+// tryUpdate to compute the new value, and stores it conditioned on the
+// observed version. On a version conflict it re-fetches and retries
+// tryUpdate until it either succeeds or returns ErrNoUpdateNeeded.
+//
+// tryUpdate receives the current raw value and its version, and returns the
+// new value to store, an optional new TTL, or an error. Returning
+// ErrNoUpdateNeeded aborts the loop without writing anything. Each conflict
+// waits out a short jittered backoff (see conflictBackoff) before retrying,
+// so competing writers don't immediately collide again.
+//
+// If out is non-nil, it must be a pointer, and the value written by the final
+// successful Store is decoded into it. A nil out skips the decode. out is left
+// untouched when tryUpdate returns ErrNoUpdateNeeded.
+func (c *Client) GuaranteedUpdate(
+	ctx context.Context,
+	token string,
+	out interface{},
+	tryUpdate func(current []byte, version int) (newValue interface{}, ttl *int, err error),
+	opts *GuaranteedUpdateOptions,
+) error {
+	var (
+		current    []byte
+		version    int
+		haveCached bool
+		conflicts  int
+	)
+	if opts != nil && opts.CachedData != nil {
+		current, version, haveCached = opts.CachedData, opts.CachedVersion, true
+	}
+
+	for {
+		if !haveCached {
+			retrieved, err := c.Retrieve(ctx, token)
+			if err != nil {
+				return err
+			}
+			current, version = retrieved.Data, retrieved.Version
+		}
+		haveCached = false
+
+		newValue, ttl, err := tryUpdate(current, version)
+		if err != nil {
+			if errors.Is(err, ErrNoUpdateNeeded) {
+				return nil
+			}
+			return err
+		}
+
+		if _, err := c.Store(ctx, newValue, token, ttl, version); err != nil {
+			if errors.Is(err, ErrConflict) {
+				conflicts++
+				select {
+				case <-time.After(conflictBackoff(conflicts)):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				continue
+			}
+			return err
+		}
+
+		if out == nil {
+			return nil
+		}
+		raw, err := json.Marshal(newValue)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(raw, out)
+	}
+}
@@ -0,0 +1,61 @@
+package keyvalue_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	kv "github.com/mikro-design/key-value.sdk/go"
+	"github.com/mikro-design/key-value.sdk/go/keyvaluetest"
+)
+
+func TestGuaranteedUpdateWithTransforms(t *testing.T) {
+	f := keyvaluetest.NewFakeServer()
+	defer f.Close()
+
+	aesTransform, err := kv.NewAESGCMTransformer([]byte("01234567890123456789012345678901"[:32]))
+	if err != nil {
+		t.Fatalf("NewAESGCMTransformer: %v", err)
+	}
+
+	c := f.Client()
+	c.Transforms = []kv.Transformer{kv.GzipTransformer{}, aesTransform}
+
+	ctx := context.Background()
+	token := "gu-token"
+
+	if _, err := c.Store(ctx, map[string]int{"count": 0}, token, nil); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		err := c.GuaranteedUpdate(ctx, token, nil, func(current []byte, version int) (interface{}, *int, error) {
+			var v struct {
+				Count int `json:"count"`
+			}
+			if err := json.Unmarshal(current, &v); err != nil {
+				return nil, nil, err
+			}
+			v.Count++
+			return v, nil, nil
+		}, nil)
+		if err != nil {
+			t.Fatalf("GuaranteedUpdate (iteration %d): %v", i, err)
+		}
+	}
+
+	resp, err := c.Retrieve(ctx, token)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+
+	var got struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal(resp.Data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Count != 3 {
+		t.Fatalf("expected count 3 after round-tripping through gzip+AES-GCM, got %d", got.Count)
+	}
+}
@@ -0,0 +1,99 @@
+package keyvalue
+
+import "context"
+
+// HistoryIterator steps through a token's History one event at a time,
+// following the pagination.before/has_more cursors so callers don't have
+// to hand-roll the paging loop themselves.
+type HistoryIterator struct {
+	client *Client
+	token  string
+	opts   HistoryOptions
+
+	events  []HistoryEvent
+	pos     int
+	before  *int
+	started bool
+	done    bool
+	err     error
+}
+
+// HistoryIterator returns a stateful iterator over token's History.
+func (c *Client) HistoryIterator(token string, opts *HistoryOptions) *HistoryIterator {
+	it := &HistoryIterator{client: c, token: token}
+	if opts != nil {
+		it.opts = *opts
+		it.before = opts.Before
+	}
+	return it
+}
+
+// Next advances the iterator, fetching the next page from the server when
+// the current page is exhausted, and reports whether an event is
+// available. It returns false when the history is exhausted, or when a
+// page fetch fails or ctx is cancelled; call Err afterwards to
+// distinguish the two.
+func (it *HistoryIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.pos >= len(it.events) {
+		if it.started && it.done {
+			return false
+		}
+		it.started = true
+
+		opts := it.opts
+		opts.Before = it.before
+
+		resp, err := it.client.History(ctx, it.token, &opts)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.events = resp.Events
+		it.pos = 0
+		it.before = resp.Pagination.Before
+		it.done = !resp.Pagination.HasMore
+
+		if len(it.events) == 0 && it.done {
+			return false
+		}
+	}
+
+	it.pos++
+	return true
+}
+
+// Event returns the event most recently made available by Next.
+func (it *HistoryIterator) Event() HistoryEvent {
+	return it.events[it.pos-1]
+}
+
+// Err returns the first error encountered while paging, if any.
+func (it *HistoryIterator) Err() error {
+	return it.err
+}
+
+// Chan returns a channel that yields events as Next makes them available,
+// for range-based consumption. The channel is closed once the iterator is
+// exhausted, fails, or ctx is cancelled; callers should check Err after the
+// channel closes to distinguish exhaustion from failure.
+func (it *HistoryIterator) Chan(ctx context.Context) <-chan HistoryEvent {
+	ch := make(chan HistoryEvent)
+
+	go func() {
+		defer close(ch)
+		for it.Next(ctx) {
+			select {
+			case ch <- it.Event():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,529 @@
+// Package keyvaluetest provides an in-memory fake of the Key-Value API for
+// use in tests, so downstream code that depends on the SDK doesn't need to
+// hit the real service.
+package keyvaluetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	kv "github.com/mikro-design/key-value.sdk/go"
+)
+
+// Clock abstracts the passage of time so tests can exercise TTL expiration
+// deterministically. NewFakeServer installs a real clock by default; set
+// FakeServer.Clock to replace it before making any requests.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// FakeServer is an in-memory implementation of the Key-Value API backed by
+// a map with version counters and TTL expiration, suitable for wiring into
+// tests via Client.
+type FakeServer struct {
+	// Clock is consulted for "now" wherever the real API would use the
+	// current time, including TTL expiration. Defaults to the real clock.
+	Clock Clock
+
+	server *httptest.Server
+
+	mu      sync.Mutex
+	records map[string]*record
+	history map[string][]kv.HistoryEvent
+	errors  map[string]kv.APIError
+}
+
+type record struct {
+	data      json.RawMessage
+	version   int
+	updatedAt time.Time
+	expiresAt *time.Time
+}
+
+// NewFakeServer starts an httptest.Server implementing the generate,
+// store, retrieve, delete, patch, history and batch endpoints of the
+// Key-Value API against an in-memory store.
+func NewFakeServer() *FakeServer {
+	f := &FakeServer{
+		Clock:   realClock{},
+		records: make(map[string]*record),
+		history: make(map[string][]kv.HistoryEvent),
+		errors:  make(map[string]kv.APIError),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/generate", f.handleGenerate)
+	mux.HandleFunc("/api/store", f.handleStore)
+	mux.HandleFunc("/api/retrieve", f.handleRetrieve)
+	mux.HandleFunc("/api/delete", f.handleDelete)
+	mux.HandleFunc("/api/history", f.handleHistory)
+	mux.HandleFunc("/api/batch", f.handleBatch)
+	f.server = httptest.NewServer(mux)
+
+	return f
+}
+
+// Client returns a Client wired to talk to this fake server.
+func (f *FakeServer) Client() *kv.Client {
+	return kv.NewClient(kv.WithBaseURL(f.server.URL))
+}
+
+// URL returns the base URL of the underlying httptest.Server.
+func (f *FakeServer) URL() string {
+	return f.server.URL
+}
+
+// Close shuts down the underlying httptest.Server.
+func (f *FakeServer) Close() {
+	f.server.Close()
+}
+
+// InjectError makes the next request to path fail with err, for
+// fault-injection testing of retry/backoff logic. The injected error is
+// consumed after a single use.
+func (f *FakeServer) InjectError(path string, err kv.APIError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors[path] = err
+}
+
+// takeInjectedError consumes and returns any error injected for path.
+func (f *FakeServer) takeInjectedError(path string) (kv.APIError, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err, ok := f.errors[path]
+	if ok {
+		delete(f.errors, path)
+	}
+	return err, ok
+}
+
+func (f *FakeServer) writeInjected(w http.ResponseWriter, path string) bool {
+	apiErr, ok := f.takeInjectedError(path)
+	if !ok {
+		return false
+	}
+	writeError(w, apiErr.StatusCode, apiErr.Message)
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func tokenFromRequest(r *http.Request) string {
+	return r.Header.Get("X-KV-Token")
+}
+
+func (f *FakeServer) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if f.writeInjected(w, "/api/generate") {
+		return
+	}
+	writeJSON(w, map[string]interface{}{"success": true, "token": generateToken()})
+}
+
+var tokenWords = []string{
+	"correct", "horse", "battery", "staple", "orbit",
+	"violet", "mango", "tundra", "quartz", "ember",
+}
+
+func generateToken() string {
+	parts := make([]string, 5)
+	for i := range parts {
+		parts[i] = tokenWords[rand.Intn(len(tokenWords))]
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", parts[0], parts[1], parts[2], parts[3], parts[4])
+}
+
+func (f *FakeServer) handleStore(w http.ResponseWriter, r *http.Request) {
+	token := tokenFromRequest(r)
+	if f.writeInjected(w, "/api/store") {
+		return
+	}
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "token is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		f.doStore(w, r, token)
+	case http.MethodPatch:
+		f.doPatch(w, r, token)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (f *FakeServer) doStore(w http.ResponseWriter, r *http.Request, token string) {
+	var body struct {
+		Data    json.RawMessage `json:"data"`
+		TTL     *int            `json:"ttl"`
+		Version *int            `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	f.mu.Lock()
+	existing := f.expire(token)
+	if body.Version != nil && (existing == nil || existing.version != *body.Version) {
+		f.mu.Unlock()
+		writeError(w, http.StatusConflict, "version mismatch")
+		return
+	}
+	rec := f.put(token, body.Data, body.TTL)
+	f.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"success":    true,
+		"message":    "stored",
+		"size":       len(body.Data),
+		"tier":       "free",
+		"version":    rec.version,
+		"updated_at": rec.updatedAt,
+		"expires_at": rec.expiresAt,
+	})
+}
+
+func (f *FakeServer) doPatch(w http.ResponseWriter, r *http.Request, token string) {
+	var body struct {
+		Version int                 `json:"version"`
+		Patch   *kv.PatchOperations `json:"patch"`
+		TTL     *int                `json:"ttl"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	f.mu.Lock()
+	existing := f.expire(token)
+	if existing == nil {
+		f.mu.Unlock()
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if existing.version != body.Version {
+		f.mu.Unlock()
+		writeError(w, http.StatusConflict, "version mismatch")
+		return
+	}
+
+	merged := map[string]interface{}{}
+	json.Unmarshal(existing.data, &merged)
+	if body.Patch != nil {
+		for k, v := range body.Patch.Set {
+			merged[k] = v
+		}
+		for _, k := range body.Patch.Remove {
+			delete(merged, k)
+		}
+	}
+	newData, _ := json.Marshal(merged)
+
+	rec := f.put(token, newData, body.TTL)
+	f.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"success":    true,
+		"version":    rec.version,
+		"updated_at": rec.updatedAt,
+		"expires_at": rec.expiresAt,
+		"data":       rec.data,
+		"size":       len(rec.data),
+		"tier":       "free",
+	})
+}
+
+// put writes a new version of token's value and appends a history event.
+// Callers must hold f.mu.
+func (f *FakeServer) put(token string, data json.RawMessage, ttl *int) *record {
+	now := f.Clock.Now()
+	version := 1
+	if existing := f.records[token]; existing != nil {
+		version = existing.version + 1
+	}
+
+	rec := &record{data: data, version: version, updatedAt: now}
+	if ttl != nil {
+		expiresAt := now.Add(time.Duration(*ttl) * time.Second)
+		rec.expiresAt = &expiresAt
+	}
+	f.records[token] = rec
+
+	f.history[token] = append(f.history[token], kv.HistoryEvent{
+		Seq:       version,
+		CreatedAt: now,
+		ExpiresAt: rec.expiresAt,
+		Payload:   data,
+	})
+
+	return rec
+}
+
+// expire removes token's record if its TTL has elapsed. Callers must hold
+// f.mu.
+func (f *FakeServer) expire(token string) *record {
+	rec := f.records[token]
+	if rec == nil {
+		return nil
+	}
+	if rec.expiresAt != nil && !f.Clock.Now().Before(*rec.expiresAt) {
+		delete(f.records, token)
+		return nil
+	}
+	return rec
+}
+
+func (f *FakeServer) handleRetrieve(w http.ResponseWriter, r *http.Request) {
+	token := tokenFromRequest(r)
+	if f.writeInjected(w, "/api/retrieve") {
+		return
+	}
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "token is required")
+		return
+	}
+
+	f.mu.Lock()
+	rec := f.expire(token)
+	f.mu.Unlock()
+
+	if rec == nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"success":    true,
+		"data":       rec.data,
+		"version":    rec.version,
+		"updated_at": rec.updatedAt,
+		"expires_at": rec.expiresAt,
+	})
+}
+
+func (f *FakeServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	token := tokenFromRequest(r)
+	if f.writeInjected(w, "/api/delete") {
+		return
+	}
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "token is required")
+		return
+	}
+
+	f.mu.Lock()
+	_, existed := f.records[token]
+	delete(f.records, token)
+	f.mu.Unlock()
+
+	if !existed {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	writeJSON(w, map[string]interface{}{"success": true, "message": "deleted"})
+}
+
+func (f *FakeServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	token := tokenFromRequest(r)
+	if f.writeInjected(w, "/api/history") {
+		return
+	}
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "token is required")
+		return
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+
+	var before *int
+	if b := r.URL.Query().Get("before"); b != "" {
+		var v int
+		if _, err := fmt.Sscanf(b, "%d", &v); err == nil {
+			before = &v
+		}
+	}
+
+	var since *time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			since = &t
+		}
+	}
+
+	typ := r.URL.Query().Get("type")
+
+	f.mu.Lock()
+	all := f.history[token]
+	events := make([]kv.HistoryEvent, 0, len(all))
+	for _, e := range all {
+		if before != nil && e.Seq >= *before {
+			continue
+		}
+		if since != nil && e.CreatedAt.Before(*since) {
+			continue
+		}
+		if typ != "" && (e.ClassifiedType == nil || *e.ClassifiedType != typ) {
+			continue
+		}
+		events = append(events, e)
+	}
+	f.mu.Unlock()
+
+	// Newest first, matching the real API's time-series ordering.
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	hasMore := false
+	if len(events) > limit {
+		events = events[:limit]
+		hasMore = true
+	}
+
+	var nextBefore *int
+	if hasMore && len(events) > 0 {
+		seq := events[len(events)-1].Seq
+		nextBefore = &seq
+	}
+
+	resp := kv.HistoryResponse{Success: true, Events: events}
+	resp.Pagination.Limit = limit
+	resp.Pagination.Before = nextBefore
+	resp.Pagination.HasMore = hasMore
+	writeJSON(w, resp)
+}
+
+func (f *FakeServer) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if f.writeInjected(w, "/api/batch") {
+		return
+	}
+
+	var body struct {
+		Operations []kv.BatchOperation `json:"operations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	results := make([]kv.BatchResult, 0, len(body.Operations))
+	succeeded := 0
+	for _, op := range body.Operations {
+		result := f.applyBatchOp(op)
+		if result.Success {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	resp := kv.BatchResponse{Success: true, Results: results}
+	resp.Summary.Total = len(results)
+	resp.Summary.Succeeded = succeeded
+	resp.Summary.Failed = len(results) - succeeded
+	if len(results) > 0 {
+		resp.Summary.SuccessRate = fmt.Sprintf("%.0f%%", float64(succeeded)/float64(len(results))*100)
+	}
+	writeJSON(w, resp)
+}
+
+func (f *FakeServer) applyBatchOp(op kv.BatchOperation) kv.BatchResult {
+	result := kv.BatchResult{Token: op.Token, Action: op.Action}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch op.Action {
+	case "store":
+		dataBytes, err := json.Marshal(op.Data)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if existing := f.expire(op.Token); op.Version != nil && (existing == nil || existing.version != *op.Version) {
+			result.Error = "version mismatch"
+			return result
+		}
+		rec := f.put(op.Token, dataBytes, op.TTL)
+		result.Success = true
+		result.Data = rec.data
+		result.Version = &rec.version
+
+	case "retrieve":
+		rec := f.expire(op.Token)
+		if rec == nil {
+			result.Error = "not found"
+			return result
+		}
+		result.Success = true
+		result.Data = rec.data
+		result.Version = &rec.version
+
+	case "delete":
+		if f.expire(op.Token) == nil {
+			result.Error = "not found"
+			return result
+		}
+		delete(f.records, op.Token)
+		result.Success = true
+
+	case "patch":
+		existing := f.expire(op.Token)
+		if existing == nil {
+			result.Error = "not found"
+			return result
+		}
+		if op.Version != nil && existing.version != *op.Version {
+			result.Error = "version mismatch"
+			return result
+		}
+
+		merged := map[string]interface{}{}
+		json.Unmarshal(existing.data, &merged)
+		if op.Patch != nil {
+			for k, v := range op.Patch.Set {
+				merged[k] = v
+			}
+			for _, k := range op.Patch.Remove {
+				delete(merged, k)
+			}
+		}
+		newData, err := json.Marshal(merged)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+
+		rec := f.put(op.Token, newData, op.TTL)
+		result.Success = true
+		result.Data = rec.data
+		result.Version = &rec.version
+
+	default:
+		result.Error = fmt.Sprintf("unknown action %q", op.Action)
+	}
+
+	return result
+}
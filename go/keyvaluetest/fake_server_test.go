@@ -0,0 +1,182 @@
+package keyvaluetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	kv "github.com/mikro-design/key-value.sdk/go"
+)
+
+func TestStoreRetrieveRoundTrip(t *testing.T) {
+	f := NewFakeServer()
+	defer f.Close()
+	c := f.Client()
+	ctx := context.Background()
+
+	storeResp, err := c.Store(ctx, map[string]string{"hello": "world"}, "tok-store", nil)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if storeResp.Version != 1 {
+		t.Fatalf("expected version 1, got %d", storeResp.Version)
+	}
+
+	retrieveResp, err := c.Retrieve(ctx, "tok-store")
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(retrieveResp.Data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got["hello"] != "world" {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	f := NewFakeServer()
+	defer f.Close()
+	c := f.Client()
+	ctx := context.Background()
+
+	storeResp, err := c.Store(ctx, map[string]interface{}{"a": 1, "b": 2}, "tok-patch", nil)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	patch := &kv.PatchOperations{Set: map[string]interface{}{"a": 3}, Remove: []string{"b"}}
+	patchResp, err := c.Patch(ctx, storeResp.Version, patch, "tok-patch", nil)
+	if err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(patchResp.Data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got["a"] != float64(3) {
+		t.Fatalf("expected a=3, got %v", got["a"])
+	}
+	if _, ok := got["b"]; ok {
+		t.Fatalf("expected b to be removed, got %v", got)
+	}
+}
+
+func TestHistoryIteratorPagination(t *testing.T) {
+	f := NewFakeServer()
+	defer f.Close()
+	c := f.Client()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.Store(ctx, i, "tok-history", nil); err != nil {
+			t.Fatalf("Store %d: %v", i, err)
+		}
+	}
+
+	it := c.HistoryIterator("tok-history", &kv.HistoryOptions{Limit: 2})
+	count := 0
+	lastSeq := 0
+	for it.Next(ctx) {
+		ev := it.Event()
+		if lastSeq != 0 && ev.Seq >= lastSeq {
+			t.Fatalf("expected strictly descending seq, got %d after %d", ev.Seq, lastSeq)
+		}
+		lastSeq = ev.Seq
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iterator: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("expected 5 events across pages, got %d", count)
+	}
+}
+
+func TestBatchStoreRetrievePatch(t *testing.T) {
+	f := NewFakeServer()
+	defer f.Close()
+	c := f.Client()
+	ctx := context.Background()
+
+	storeResp, err := c.Store(ctx, map[string]interface{}{"a": 1}, "tok-batch", nil)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	resp, err := c.Batch(ctx, []kv.BatchOperation{
+		{
+			Action:  "patch",
+			Token:   "tok-batch",
+			Version: &storeResp.Version,
+			Patch:   &kv.PatchOperations{Set: map[string]interface{}{"a": 2}},
+		},
+		{Action: "retrieve", Token: "tok-batch"},
+	})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if resp.Summary.Failed != 0 {
+		t.Fatalf("expected all operations to succeed, got %+v", resp.Summary)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(resp.Results[1].Data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got["a"] != float64(2) {
+		t.Fatalf("expected a=2 after batch patch, got %v", got["a"])
+	}
+}
+
+// fakeClock is a settable Clock for deterministic TTL tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestTTLExpiry(t *testing.T) {
+	f := NewFakeServer()
+	defer f.Close()
+	clock := &fakeClock{now: time.Now()}
+	f.Clock = clock
+	c := f.Client()
+	ctx := context.Background()
+
+	ttl := 1
+	if _, err := c.Store(ctx, "v", "tok-ttl", &ttl); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	if _, err := c.Retrieve(ctx, "tok-ttl"); err != nil {
+		t.Fatalf("expected value present before expiry: %v", err)
+	}
+
+	clock.now = clock.now.Add(2 * time.Second)
+
+	if _, err := c.Retrieve(ctx, "tok-ttl"); err == nil {
+		t.Fatalf("expected not found after TTL expiry")
+	}
+}
+
+func TestInjectError(t *testing.T) {
+	f := NewFakeServer()
+	defer f.Close()
+	c := f.Client()
+	ctx := context.Background()
+
+	f.InjectError("/api/store", kv.APIError{StatusCode: 429, Message: "slow down"})
+
+	if _, err := c.Store(ctx, "v", "tok-inject", nil); err == nil {
+		t.Fatalf("expected injected error")
+	}
+
+	// The injected error is consumed after a single use.
+	if _, err := c.Store(ctx, "v", "tok-inject", nil); err != nil {
+		t.Fatalf("expected second store to succeed, got: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package keyvalue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sentinel errors for common API failure conditions. Use errors.Is to check
+// for these against an error returned by any Client method; they are
+// surfaced through APIError.Unwrap.
+var (
+	ErrConflict     = errors.New("key-value: version conflict")
+	ErrNotFound     = errors.New("key-value: not found")
+	ErrRateLimited  = errors.New("key-value: rate limited")
+	ErrUnauthorized = errors.New("key-value: unauthorized")
+)
+
+// RetryPolicy controls how a Client retries transient failures (network
+// errors, 429, and 5xx responses). The zero value disables retries,
+// matching Vault's decision to make retries opt-in rather than surprise
+// callers with extra latency or duplicate side effects.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// 0 or 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the base of the exponential backoff between attempts.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay, before jitter is applied.
+	MaxDelay time.Duration
+	// MaxAttemptsByMethod overrides MaxAttempts for specific HTTP methods
+	// (e.g. "GET"), for callers who want retries on idempotent reads but
+	// not on POST/PATCH.
+	MaxAttemptsByMethod map[string]int
+}
+
+// DefaultRetryPolicy returns a reasonable opt-in policy: up to 3 attempts
+// with exponential backoff from a 200ms base, capped at 5s, and full jitter
+// (each delay is drawn uniformly from zero up to that backoff).
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// WithRetryPolicy enables retries using policy. By default, a Client
+// performs no retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+func (p RetryPolicy) maxAttempts(method string) int {
+	if n, ok := p.MaxAttemptsByMethod[method]; ok {
+		if n < 1 {
+			return 1
+		}
+		return n
+	}
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the exponential delay, with full jitter, before the given
+// attempt number (1-indexed: the delay before the second attempt overall).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = 200 * time.Millisecond
+	}
+	max := p.MaxDelay
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+
+	delay := base << uint(attempt-1)
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// waitBeforeRetry sleeps before the next attempt, honoring a server's
+// Retry-After header on lastErr if present, or falling back to the
+// policy's exponential-backoff-with-jitter delay.
+func (c *Client) waitBeforeRetry(ctx context.Context, attempt int, lastErr error) error {
+	delay := c.RetryPolicy.backoff(attempt)
+
+	var apiErr *APIError
+	if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > 0 {
+		delay = apiErr.RetryAfter
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, which per RFC 7231 is
+// either a number of seconds or an HTTP date. It returns 0 if header is
+// empty or unparsable.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Retryable reports whether err represents a transient failure (network
+// error, 429, or 5xx) worth retrying. It is exposed so callers with their
+// own retry loops can reuse the client's classification.
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+
+	// A response that arrived but could not be parsed or decoded is not a
+	// transient failure.
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var transformErr *TransformError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &transformErr) {
+		return false
+	}
+
+	// Anything else reaching this point (network errors, timeouts, EOF from
+	// a dropped connection) means the request never got an HTTP response at
+	// all, which is also worth retrying.
+	return true
+}
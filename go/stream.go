@@ -0,0 +1,68 @@
+package keyvalue
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// watchStream wraps the body of a long-lived HTTP connection (currently
+// Watch, with Batch streaming as a future consumer) so callers can apply
+// independent read/write deadlines to a single operation without cancelling
+// the context that opened the connection. Because the underlying net/http
+// body has no native deadline support, a deadline is enforced by closing
+// the body when it elapses, which unblocks any in-flight Read with an
+// error, mirroring the shared-cancellation-channel pattern used for per-op
+// deadlines elsewhere in the Go net stack.
+type watchStream struct {
+	body io.ReadCloser
+
+	mu        sync.Mutex
+	readTimer *time.Timer
+}
+
+func newWatchStream(body io.ReadCloser) *watchStream {
+	return &watchStream{body: body}
+}
+
+func (s *watchStream) Read(p []byte) (int, error) {
+	return s.body.Read(p)
+}
+
+func (s *watchStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.readTimer != nil {
+		s.readTimer.Stop()
+	}
+	return s.body.Close()
+}
+
+// SetReadDeadline arms a timer that closes the stream when t passes,
+// unblocking the current Read, and failing any later one, with an error. A
+// completed Read does not disarm it; call SetReadDeadline again to move the
+// deadline. The zero Time clears any armed deadline.
+func (s *watchStream) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.readTimer != nil {
+		s.readTimer.Stop()
+		s.readTimer = nil
+	}
+	if t.IsZero() {
+		return nil
+	}
+
+	s.readTimer = time.AfterFunc(time.Until(t), func() {
+		s.body.Close()
+	})
+	return nil
+}
+
+// SetWriteDeadline exists for symmetry with SetReadDeadline and for future
+// bidirectional streams; the watch protocol is read-only today, so it is a
+// no-op.
+func (s *watchStream) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,289 @@
+package keyvalue
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Transformer transforms a value's encoded bytes before it is sent to the
+// server (Encode) and after it is received back (Decode), e.g. to compress
+// or encrypt it client-side. A Client applies its Transforms in order on
+// the way out, and in reverse order on the way back in.
+type Transformer interface {
+	Encode(data []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// WithTransform sets the Transform chain applied to values around Store,
+// Retrieve, Patch, Batch and History.
+func WithTransform(transformers ...Transformer) Option {
+	return func(c *Client) {
+		c.Transforms = transformers
+	}
+}
+
+// TransformError is returned when a stored value cannot be decoded by the
+// configured Codec/Transform chain, e.g. because it was encrypted under a
+// different key.
+type TransformError struct {
+	Token string
+	Err   error
+}
+
+func (e *TransformError) Error() string {
+	return fmt.Sprintf("key-value: failed to decode transformed value for token %q: %v", e.Token, e.Err)
+}
+
+func (e *TransformError) Unwrap() error { return e.Err }
+
+// usesTransform reports whether values round-tripped through this Client
+// need the blob encoding applied by encodeValue/decodeStoredValue, as
+// opposed to being embedded directly as plain JSON.
+func (c *Client) usesTransform() bool {
+	return c.Codec != nil || len(c.Transforms) > 0
+}
+
+// encodeValue runs v through the Codec and Transform chain and
+// base64-encodes the result for embedding in a JSON request body. ok is
+// false when no Codec or Transform is configured, meaning the caller
+// should embed v directly instead, preserving the plain-JSON wire format.
+func (c *Client) encodeValue(v interface{}) (encoded string, ok bool, err error) {
+	if !c.usesTransform() {
+		return "", false, nil
+	}
+
+	data, err := c.codec().Marshal(v)
+	if err != nil {
+		return "", true, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	for _, t := range c.Transforms {
+		if data, err = t.Encode(data); err != nil {
+			return "", true, fmt.Errorf("failed to encode value: %w", err)
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(data), true, nil
+}
+
+// encodePatch runs every value in patch.Set through encodeValue, returning
+// a new PatchOperations so the original is left untouched. Patch and Batch
+// both call this so a transformed client never sends a plaintext value
+// through either path. If no Codec/Transform is configured, or patch has no
+// Set entries, patch is returned unchanged.
+func (c *Client) encodePatch(patch *PatchOperations) (*PatchOperations, error) {
+	if patch == nil || !c.usesTransform() || len(patch.Set) == 0 {
+		return patch, nil
+	}
+
+	encoded := &PatchOperations{Set: make(map[string]interface{}, len(patch.Set)), Remove: patch.Remove}
+	for k, v := range patch.Set {
+		value, _, err := c.encodeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		encoded.Set[k] = value
+	}
+	return encoded, nil
+}
+
+// decodeStoredValue reverses encodeValue. raw is expected to be a JSON
+// string holding the base64 blob produced by encodeValue; values that are not
+// JSON strings (e.g. objects written before transforms were enabled) are
+// passed through unchanged. A plain JSON string written without transforms
+// cannot be told apart from a blob and fails with a TransformError.
+func (c *Client) decodeStoredValue(token string, raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || !c.usesTransform() {
+		return raw, nil
+	}
+
+	var blob string
+	if err := json.Unmarshal(raw, &blob); err != nil {
+		// Not a transformed blob (e.g. stored before transforms were
+		// enabled on this client); pass the value through unchanged.
+		return raw, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, &TransformError{Token: token, Err: err}
+	}
+
+	for i := len(c.Transforms) - 1; i >= 0; i-- {
+		if data, err = c.Transforms[i].Decode(data); err != nil {
+			return nil, &TransformError{Token: token, Err: err}
+		}
+	}
+
+	var v interface{}
+	if err := c.codec().Unmarshal(data, &v); err != nil {
+		return nil, &TransformError{Token: token, Err: err}
+	}
+
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return nil, &TransformError{Token: token, Err: err}
+	}
+	return plain, nil
+}
+
+// GzipTransformer compresses values with gzip before they are sent to the
+// server and decompresses them on the way back. Values smaller than MinSize
+// are passed through uncompressed so tiny values aren't penalized by
+// gzip's fixed overhead.
+//
+// A zstd transformer is not provided: zstd has no implementation in the
+// standard library, and this module ships with no dependency manifest to
+// vendor one against, so adding one here would not build. GzipTransformer
+// satisfies the same Transformer interface, so a zstd-backed transformer
+// (e.g. wrapping klauspost/compress/zstd) can be dropped in later without
+// any change to Client once the module takes on dependencies.
+type GzipTransformer struct {
+	// MinSize is the smallest value, in bytes, that gets compressed.
+	// Defaults to 256 bytes if zero.
+	MinSize int
+}
+
+const (
+	gzipMarkerRaw        byte = 0
+	gzipMarkerCompressed byte = 1
+)
+
+func (t GzipTransformer) minSize() int {
+	if t.MinSize > 0 {
+		return t.MinSize
+	}
+	return 256
+}
+
+func (t GzipTransformer) Encode(data []byte) ([]byte, error) {
+	if len(data) < t.minSize() {
+		return append([]byte{gzipMarkerRaw}, data...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(gzipMarkerCompressed)
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("gzip transformer: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("gzip transformer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (t GzipTransformer) Decode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("gzip transformer: empty input")
+	}
+
+	marker, body := data[0], data[1:]
+	if marker == gzipMarkerRaw {
+		return body, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gzip transformer: %w", err)
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// AESGCMTransformer provides zero-knowledge, client-side envelope
+// encryption: each value is encrypted under its own random data key, and
+// only that data key (itself encrypted under masterKey) travels alongside
+// the ciphertext, so the server never sees plaintext or masterKey.
+type AESGCMTransformer struct {
+	masterKey cipher.AEAD
+}
+
+// NewAESGCMTransformer builds an AESGCMTransformer that encrypts data keys
+// with AES-GCM under masterKey, which must be 16, 24, or 32 bytes (AES-128,
+// AES-192, or AES-256).
+func NewAESGCMTransformer(masterKey []byte) (*AESGCMTransformer, error) {
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("aes-gcm transformer: %w", err)
+	}
+	return &AESGCMTransformer{masterKey: aead}, nil
+}
+
+// aesEnvelope is the wire format produced by AESGCMTransformer.Encode.
+// []byte fields marshal as base64 under encoding/json.
+type aesEnvelope struct {
+	EncryptedDataKey []byte `json:"edk"`
+	DataKeyNonce     []byte `json:"dkn"`
+	Nonce            []byte `json:"n"`
+	Ciphertext       []byte `json:"ct"`
+}
+
+func (t *AESGCMTransformer) Encode(data []byte) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("aes-gcm transformer: %w", err)
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, dataAEAD.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("aes-gcm transformer: %w", err)
+	}
+	ciphertext := dataAEAD.Seal(nil, nonce, data, nil)
+
+	dkNonce := make([]byte, t.masterKey.NonceSize())
+	if _, err := rand.Read(dkNonce); err != nil {
+		return nil, fmt.Errorf("aes-gcm transformer: %w", err)
+	}
+	encryptedDataKey := t.masterKey.Seal(nil, dkNonce, dataKey, nil)
+
+	return json.Marshal(aesEnvelope{
+		EncryptedDataKey: encryptedDataKey,
+		DataKeyNonce:     dkNonce,
+		Nonce:            nonce,
+		Ciphertext:       ciphertext,
+	})
+}
+
+func (t *AESGCMTransformer) Decode(data []byte) ([]byte, error) {
+	var env aesEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("aes-gcm transformer: %w", err)
+	}
+
+	dataKey, err := t.masterKey.Open(nil, env.DataKeyNonce, env.EncryptedDataKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("aes-gcm transformer: %w", err)
+	}
+
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := dataAEAD.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("aes-gcm transformer: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
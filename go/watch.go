@@ -0,0 +1,261 @@
+package keyvalue
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WatchEventKind identifies the kind of change observed by a Watch.
+type WatchEventKind string
+
+const (
+	WatchPut    WatchEventKind = "Put"
+	WatchPatch  WatchEventKind = "Patch"
+	WatchDelete WatchEventKind = "Delete"
+	WatchExpire WatchEventKind = "Expire"
+
+	// watchBookmark is sent periodically on an otherwise idle stream so
+	// slow consumers can tell the connection is still alive. It carries no
+	// data change and is not delivered to callers.
+	watchBookmark WatchEventKind = "Bookmark"
+)
+
+// WatchEvent describes a single change observed on a watched token.
+type WatchEvent struct {
+	Kind      WatchEventKind  `json:"kind"`
+	Version   int             `json:"version"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Patch     json.RawMessage `json:"patch,omitempty"`
+}
+
+// WatchOptions configures a Watch call.
+type WatchOptions struct {
+	// ResumeFromVersion resumes a watch from a previously observed version,
+	// mirroring the resourceVersion pattern used by etcd/Kubernetes watches.
+	// If the server can no longer satisfy the resume point because it has
+	// aged out of its buffer, Watch seeds the stream from History instead
+	// of returning an error.
+	ResumeFromVersion int
+}
+
+const (
+	watchInitialBackoff = 500 * time.Millisecond
+	watchMaxBackoff     = 30 * time.Second
+
+	// watchReadIdleTimeout bounds how long watchOnce waits for the next
+	// message on an otherwise-silent connection before giving up on it and
+	// letting watchLoop reconnect. It is enforced independently of ctx via
+	// watchStream's deadline, so a watch with no caller-supplied deadline
+	// still notices a connection that has gone quiet without the server
+	// ever closing it.
+	watchReadIdleTimeout = 90 * time.Second
+)
+
+// errWatchResumeStale is returned internally by watchOnce when the server
+// reports that ResumeFromVersion has fallen out of its retention window
+// (HTTP 410 Gone), signaling that the stream must be seeded from History.
+type errWatchResumeStale struct{}
+
+func (errWatchResumeStale) Error() string { return "watch: resume version is stale" }
+
+// Watch opens a long-lived streaming connection that observes Put, Patch,
+// Delete and Expire events for token's value. The returned channel is closed
+// only when ctx is cancelled; every stream failure, including an error
+// response from the server, is retried rather than ending the watch. Callers
+// should range over it rather than polling History.
+//
+// Watch reconnects automatically with exponential backoff on transient
+// stream failures, resuming from the last version it observed (or
+// opts.ResumeFromVersion on the first connection).
+func (c *Client) Watch(ctx context.Context, token string, opts *WatchOptions) (<-chan WatchEvent, error) {
+	if token == "" {
+		token = c.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+
+	resumeFrom := 0
+	if opts != nil {
+		resumeFrom = opts.ResumeFromVersion
+	}
+
+	events := make(chan WatchEvent)
+
+	go c.watchLoop(ctx, token, resumeFrom, events)
+
+	return events, nil
+}
+
+// watchLoop owns the channel returned by Watch: it reconnects with
+// exponential backoff until ctx is cancelled, then closes events.
+func (c *Client) watchLoop(ctx context.Context, token string, resumeFrom int, events chan<- WatchEvent) {
+	defer close(events)
+
+	backoff := watchInitialBackoff
+	lastVersion := resumeFrom
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		version, sawActivity, err := c.watchOnce(ctx, token, lastVersion, events)
+		if version > lastVersion {
+			lastVersion = version
+		}
+		if sawActivity {
+			// The previous connection was healthy for a while (it scanned at
+			// least one message) before it dropped, so this is a routine
+			// reconnect rather than a persistent failure; don't keep
+			// penalizing it with an ever-growing delay.
+			backoff = watchInitialBackoff
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if _, stale := err.(errWatchResumeStale); stale {
+			seeded, seedErr := c.seedWatchFromHistory(ctx, token, lastVersion, events)
+			if seedErr == nil {
+				lastVersion = seeded
+				backoff = watchInitialBackoff
+				continue
+			}
+		}
+
+		// full jitter, matching the retry policy used elsewhere in the client
+		delay := time.Duration(rand.Int63n(int64(backoff) + 1))
+		if delay < watchInitialBackoff {
+			delay = watchInitialBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
+
+// watchOnce dials a single streaming connection and delivers events to
+// events until the stream ends or ctx is cancelled. It returns the highest
+// version observed so the caller can resume from it, and whether it scanned
+// at least one message, so the caller can tell a routine reconnect (after a
+// healthy connection) apart from a connection that never got off the
+// ground. Each read is bounded by watchReadIdleTimeout via watchStream, so a
+// connection that stops producing bytes without the server ever closing it
+// is still noticed and torn down for watchLoop to reconnect.
+func (c *Client) watchOnce(ctx context.Context, token string, resumeFrom int, events chan<- WatchEvent) (version int, sawActivity bool, err error) {
+	path := fmt.Sprintf("/api/watch?resume=%d", resumeFrom)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path, nil)
+	if err != nil {
+		return resumeFrom, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-KV-Token", token)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return resumeFrom, false, fmt.Errorf("watch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		return resumeFrom, false, errWatchResumeStale{}
+	}
+	if resp.StatusCode >= 400 {
+		return resumeFrom, false, &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
+	}
+
+	stream := newWatchStream(resp.Body)
+	defer stream.Close()
+
+	lastVersion := resumeFrom
+	scanner := bufio.NewScanner(stream)
+
+	stream.SetReadDeadline(time.Now().Add(watchReadIdleTimeout))
+
+	for scanner.Scan() {
+		sawActivity = true
+		stream.SetReadDeadline(time.Now().Add(watchReadIdleTimeout))
+
+		line := strings.TrimPrefix(scanner.Text(), "data: ")
+		if line == "" {
+			continue
+		}
+
+		var evt WatchEvent
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			continue
+		}
+		if evt.Version > lastVersion {
+			lastVersion = evt.Version
+		}
+		if evt.Kind == watchBookmark {
+			continue
+		}
+
+		select {
+		case events <- evt:
+		case <-ctx.Done():
+			return lastVersion, sawActivity, ctx.Err()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return lastVersion, sawActivity, fmt.Errorf("watch stream closed: %w", err)
+	}
+	return lastVersion, sawActivity, fmt.Errorf("watch stream ended")
+}
+
+// seedWatchFromHistory replays events the caller may have missed while its
+// resume point was aging out of the server's buffer, and returns the
+// version to resume streaming from afterwards.
+func (c *Client) seedWatchFromHistory(ctx context.Context, token string, resumeFrom int, events chan<- WatchEvent) (int, error) {
+	hist, err := c.History(ctx, token, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	lastVersion := 0
+	// History lists events newest first; replay them oldest first, skipping
+	// versions at or below resumeFrom, which the caller has already seen.
+	for i := len(hist.Events) - 1; i >= 0; i-- {
+		e := hist.Events[i]
+		if e.Seq > lastVersion {
+			lastVersion = e.Seq
+		}
+		if e.Seq <= resumeFrom {
+			continue
+		}
+		evt := WatchEvent{
+			Kind:      WatchPut,
+			Version:   e.Seq,
+			UpdatedAt: e.CreatedAt,
+			Data:      e.Payload,
+		}
+
+		select {
+		case events <- evt:
+		case <-ctx.Done():
+			return lastVersion, ctx.Err()
+		}
+	}
+
+	return lastVersion, nil
+}